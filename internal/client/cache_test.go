@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	unstructuredv1 "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+var widgetGVR = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+
+func newWidget(ns, name string) *unstructuredv1.Unstructured {
+	u := &unstructuredv1.Unstructured{}
+	u.SetAPIVersion("example.com/v1")
+	u.SetKind("Widget")
+	u.SetNamespace(ns)
+	u.SetName(name)
+	return u
+}
+
+// newTestCachedClient builds a cachedClient whose sole informer is synced
+// against a fake dynamic client seeded with objs, bypassing ToCachedClient's
+// discovery & ctx.Done()-driven startup.
+func newTestCachedClient(t *testing.T, flags *Flags, objs ...runtime.Object) *cachedClient {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	listKinds := map[schema.GroupVersionResource]string{widgetGVR: "WidgetList"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, listKinds, objs...)
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(dyn, 0)
+	informer := factory.ForResource(widgetGVR).Informer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		t.Fatal("informer cache never synced")
+	}
+
+	return &cachedClient{
+		client: &client{
+			configFlags:   flags,
+			dynamicClient: dyn,
+		},
+		factory:   factory,
+		informers: map[schema.GroupVersionResource]cache.SharedIndexInformer{widgetGVR: informer},
+	}
+}
+
+func TestCachedClientListReadsAllNamespaces(t *testing.T) {
+	cc := newTestCachedClient(t, &Flags{},
+		newWidget("ns-a", "foo"),
+		newWidget("ns-b", "bar"),
+	)
+
+	list, err := cc.List(context.Background(), ListOptions{
+		APIResources: []APIResource{{Group: "example.com", Version: "v1", Name: "widgets", Kind: "Widget", Namespaced: true}},
+	})
+	if err != nil {
+		t.Fatalf("List() returned error: %v", err)
+	}
+	if got, want := len(list.Items), 2; got != want {
+		t.Fatalf("got %d cached items, want %d; a namespace ByIndex(\"\") regression would silently return 0", got, want)
+	}
+}
+
+func TestCachedClientListEnforcesDeniedNamespace(t *testing.T) {
+	cc := newTestCachedClient(t, &Flags{DeniedNamespaces: []string{"ns-b"}},
+		newWidget("ns-a", "foo"),
+		newWidget("ns-b", "bar"),
+	)
+
+	list, err := cc.List(context.Background(), ListOptions{
+		APIResources: []APIResource{{Group: "example.com", Version: "v1", Name: "widgets", Kind: "Widget", Namespaced: true}},
+	})
+	if err != nil {
+		t.Fatalf("List() returned error: %v", err)
+	}
+	if got, want := len(list.Items), 1; got != want {
+		t.Fatalf("got %d cached items, want %d", got, want)
+	}
+	if got := list.Items[0].GetNamespace(); got != "ns-a" {
+		t.Fatalf("got item from namespace %q, want only items from the non-denied ns-a", got)
+	}
+}
+
@@ -3,10 +3,12 @@ package client
 import (
 	"context"
 	"fmt"
+	"path"
 	"strings"
 	"sync"
+	"time"
 
-	"golang.org/x/sync/errgroup"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -15,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 )
@@ -32,6 +35,76 @@ type GetOptions struct {
 type ListOptions struct {
 	APIResources []APIResource
 	Namespaces   []string
+
+	// CheckAccess, when true, consults SelfSubjectRulesReview up-front to
+	// compute the {namespace, API resource} pairs the caller can actually
+	// list, and skips dispatching requests outside of that set. This
+	// avoids paying for a round-trip per forbidden namespace/resource on
+	// clusters where the caller only has access to a handful of
+	// namespaces.
+	CheckAccess bool
+
+	// LabelSelector & FieldSelector, when set, are passed through to every
+	// underlying List request.
+	LabelSelector string
+	FieldSelector string
+
+	// IncludeAPIResources & ExcludeAPIResources filter the set of
+	// discovered API resources to traverse. Each entry is a glob (as
+	// matched by path.Match) against "group/kind", e.g. "*.k8s.io/*" or
+	// "*/Event". IncludeAPIResources, if non-empty, restricts discovery to
+	// only matching resources; ExcludeAPIResources then removes any
+	// matching resources from what's left.
+	IncludeAPIResources []string
+	ExcludeAPIResources []string
+}
+
+// resolveListOptions fills any of opts's selector/filter fields that the
+// caller left at their zero value from the ambient values configured via
+// Flags (e.g. --selector, --field-selector, --include-resource &
+// --exclude-resource), so a caller that only wires up Flags still gets the
+// behavior those flags describe without repeating them on every ListOptions.
+// Fields the caller did set take precedence.
+func (c *client) resolveListOptions(opts ListOptions) ListOptions {
+	if opts.LabelSelector == "" {
+		opts.LabelSelector = c.configFlags.LabelSelector
+	}
+	if opts.FieldSelector == "" {
+		opts.FieldSelector = c.configFlags.FieldSelector
+	}
+	if len(opts.IncludeAPIResources) == 0 {
+		opts.IncludeAPIResources = c.configFlags.IncludeAPIResources
+	}
+	if len(opts.ExcludeAPIResources) == 0 {
+		opts.ExcludeAPIResources = c.configFlags.ExcludeAPIResources
+	}
+	return opts
+}
+
+// ListError aggregates the failures encountered listing individual API
+// resources during a single List call. A non-nil ListError is returned
+// alongside the objects that were successfully listed from the other API
+// resources, so that one forbidden or failing GVR doesn't prevent reporting
+// results & failures for the rest.
+type ListError struct {
+	Errors []error
+}
+
+func (e *ListError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("failed to list %d API resource(s): %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap allows errors.Is/errors.As to match against any of the aggregated
+// errors.
+func (e *ListError) Unwrap() []error {
+	return e.Errors
 }
 
 type Interface interface {
@@ -43,9 +116,10 @@ type Interface interface {
 type client struct {
 	configFlags *Flags
 
-	discoveryClient discovery.DiscoveryInterface
-	dynamicClient   dynamic.Interface
-	mapper          meta.RESTMapper
+	discoveryClient     discovery.DiscoveryInterface
+	dynamicClient       dynamic.Interface
+	authorizationClient authorizationv1client.AuthorizationV1Interface
+	mapper              meta.RESTMapper
 }
 
 func (f *Flags) ToClient() (Interface, error) {
@@ -66,15 +140,20 @@ func (f *Flags) ToClient() (Interface, error) {
 	if err != nil {
 		return nil, err
 	}
+	authz, err := authorizationv1client.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
 	mapper, err := f.ToRESTMapper()
 	if err != nil {
 		return nil, err
 	}
 	cli := &client{
-		configFlags:     f,
-		discoveryClient: dis,
-		dynamicClient:   dyn,
-		mapper:          mapper,
+		configFlags:         f,
+		discoveryClient:     dis,
+		dynamicClient:       dyn,
+		authorizationClient: authz,
+		mapper:              mapper,
 	}
 
 	return cli, nil
@@ -150,11 +229,12 @@ func (c *client) Get(ctx context.Context, name string, opts GetOptions) (*unstru
 
 //nolint:funlen,gocognit
 func (c *client) List(ctx context.Context, opts ListOptions) (*unstructuredv1.UnstructuredList, error) {
+	opts = c.resolveListOptions(opts)
 	klog.V(4).Infof("List with options: %+v", opts)
 	var err error
 	apis := opts.APIResources
 	if len(apis) == 0 {
-		apis, err = c.getAPIResources(ctx)
+		apis, err = c.getAPIResources(ctx, opts.IncludeAPIResources, opts.ExcludeAPIResources)
 		if err != nil {
 			return nil, err
 		}
@@ -168,83 +248,229 @@ func (c *client) List(ctx context.Context, opts ListOptions) (*unstructuredv1.Un
 	}
 	for _, ns := range opts.Namespaces {
 		if ns != "" {
+			if !c.configFlags.isNamespaceAllowed(ns) {
+				klog.V(4).Infof("Excluding namespace %q: disallowed by --allow-namespace/--deny-namespace", ns)
+				continue
+			}
 			nsSet[ns] = struct{}{}
 		} else {
 			isClusterScopeRequest = true
 		}
 	}
 
+	// When an allow-list is configured, a cluster-scope request can't be
+	// satisfied with a single cluster-wide List (it would reach into
+	// disallowed namespaces); decompose it into one List per allowed
+	// namespace for namespaced resources instead. Cluster-scoped
+	// resources are unaffected & still listed once.
+	decomposeClusterScopeRequest := isClusterScopeRequest && len(c.configFlags.AllowedNamespaces) > 0
+	if decomposeClusterScopeRequest {
+		for _, ns := range c.configFlags.AllowedNamespaces {
+			if c.configFlags.isNamespaceAllowed(ns) {
+				nsSet[ns] = struct{}{}
+			}
+		}
+	}
+
+	var access *accessMatrix
+	if opts.CheckAccess {
+		scopes := []string{""}
+		for ns := range nsSet {
+			scopes = append(scopes, ns)
+		}
+		access, err = c.newAccessMatrix(ctx, scopes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Bound how many List requests are in flight at once: a cluster with
+	// many CRDs & namespaces fans out to far more goroutines than the API
+	// server can comfortably serve concurrently, which trips APF/429s
+	// despite the client's own QPS/Burst settings.
+	sem := make(chan struct{}, c.configFlags.maxConcurrency())
+	acquire := func(ctx context.Context) error {
+		select {
+		case sem <- struct{}{}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	release := func() { <-sem }
+
 	var mu sync.Mutex
 	var items []unstructuredv1.Unstructured
+	var listErr ListError
+	recordErr := func(err error) {
+		if err == nil {
+			return
+		}
+		mu.Lock()
+		listErr.Errors = append(listErr.Errors, err)
+		mu.Unlock()
+	}
 	createListFn := func(ctx context.Context, api APIResource, ns string) func() error {
 		return func() error {
-			objects, err := c.listByAPI(ctx, api, ns)
+			if err := acquire(ctx); err != nil {
+				return err
+			}
+			defer release()
+			objects, err := c.listByAPI(ctx, api, ns, opts.LabelSelector, opts.FieldSelector)
 			if err != nil {
 				return err
 			}
 			mu.Lock()
-			items = append(items, objects.Items...)
+			for i := range objects.Items {
+				obj := objects.Items[i]
+				// A cluster-wide List of a namespaced resource can still
+				// surface objects in a denied namespace; drop those here.
+				if api.Namespaced && !c.configFlags.isNamespaceAllowed(obj.GetNamespace()) {
+					continue
+				}
+				items = append(items, obj)
+			}
 			mu.Unlock()
 			return nil
 		}
 	}
-	eg, ctx := errgroup.WithContext(ctx)
+	var wg sync.WaitGroup
 	for i := range apis {
 		api := apis[i]
 		clusterScopeListFn := func() error {
+			if !access.allows(api, "") {
+				return errAccessDenied(api)
+			}
 			return createListFn(ctx, api, "")()
 		}
-		namespaceScopeListFn := func() error {
-			egInner, ctxInner := errgroup.WithContext(ctx)
+		namespaceScopeListFn := func() {
+			var wgInner sync.WaitGroup
 			for ns := range nsSet {
-				listFn := createListFn(ctxInner, api, ns)
-				egInner.Go(func() error {
-					err = listFn()
+				if !access.allows(api, ns) {
+					continue
+				}
+				ns := ns
+				wgInner.Add(1)
+				go func() {
+					defer wgInner.Done()
+					err := createListFn(ctx, api, ns)()
 					// If no permissions to list the resource at the namespace scope,
 					// suppress the error to allow other goroutines to continue listing
 					if apierrors.IsForbidden(err) {
-						err = nil
+						return
 					}
-					return err
-				})
+					recordErr(err)
+				}()
 			}
-			return egInner.Wait()
+			wgInner.Wait()
 		}
-		eg.Go(func() error {
-			var err error
-			if isClusterScopeRequest {
-				err = clusterScopeListFn()
-				// If no permissions to list the cluster-scoped resource,
-				// suppress the error to allow other goroutines to continue listing
-				if !api.Namespaced && apierrors.IsForbidden(err) {
-					err = nil
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isClusterScopeRequest && !(decomposeClusterScopeRequest && api.Namespaced) {
+				err := clusterScopeListFn()
+				if !api.Namespaced {
+					// If no permissions to list the cluster-scoped resource,
+					// suppress the error to allow other goroutines to continue listing
+					if apierrors.IsForbidden(err) {
+						err = nil
+					}
+					recordErr(err)
+					return
 				}
 				// If no permissions to list the namespaced resource at the cluster
-				// scope, don't return the error yet & reattempt to list the resource
+				// scope, don't record the error yet & reattempt to list the resource
 				// in other namespace(s)
-				if !api.Namespaced || !apierrors.IsForbidden(err) {
-					return err
+				if !apierrors.IsForbidden(err) {
+					recordErr(err)
+					return
 				}
 			}
-			return namespaceScopeListFn()
-		})
-	}
-	if err := eg.Wait(); err != nil {
-		return nil, err
+			namespaceScopeListFn()
+		}()
 	}
+	wg.Wait()
 
 	klog.V(4).Infof("Got %4d objects from %d API resources", len(items), len(apis))
-	return &unstructuredv1.UnstructuredList{Items: items}, nil
+	list := &unstructuredv1.UnstructuredList{Items: items}
+	if len(listErr.Errors) > 0 {
+		return list, &listErr
+	}
+	return list, nil
+}
+
+// accessMatrix records, per scope (the empty string denotes the cluster
+// scope), the "group/resource" pairs a SelfSubjectRulesReview says the
+// caller can list. A nil *accessMatrix imposes no restriction, which is
+// also what List falls back to when the review can't be computed.
+type accessMatrix struct {
+	allowed map[string]sets.String
+}
+
+// allows reports whether api can be listed within scope according to m.
+func (m *accessMatrix) allows(api APIResource, scope string) bool {
+	if m == nil {
+		return true
+	}
+	allowed, ok := m.allowed[scope]
+	if !ok {
+		return false
+	}
+	return allowed.HasAny(api.Group+"/"+api.Name, api.Group+"/*", "*/"+api.Name, "*/*")
+}
+
+// errAccessDenied returns a synthetic Forbidden error for api, so that a
+// denial from the access-matrix pre-filter is indistinguishable from a real
+// 403 from the API server to the rest of List's error handling (namely, the
+// cluster-scope-then-retry-per-namespace fallback for namespaced resources).
+func errAccessDenied(api APIResource) error {
+	return apierrors.NewForbidden(api.GroupVersionResource().GroupResource(), "", fmt.Errorf("denied by access-matrix pre-filter"))
+}
+
+// newAccessMatrix consults SelfSubjectRulesReview once per scope (the
+// cluster scope plus every namespace under consideration) to compute the
+// {scope, API resource} pairs the caller can actually list, so List can
+// skip dispatching requests that are already known to be forbidden instead
+// of paying for the round-trip. If the review can't be performed (e.g. the
+// server doesn't support it), newAccessMatrix returns a nil matrix and List
+// falls back to attempting every request as before.
+func (c *client) newAccessMatrix(ctx context.Context, scopes []string) (*accessMatrix, error) {
+	m := &accessMatrix{allowed: make(map[string]sets.String, len(scopes))}
+	for _, scope := range scopes {
+		review, err := c.authorizationClient.SelfSubjectRulesReviews().Create(ctx, &authorizationv1.SelfSubjectRulesReview{
+			Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: scope},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			klog.V(4).Infof("Unable to compute SelfSubjectRulesReview for namespace %q, disabling RBAC pre-filter: %v", scope, err)
+			return nil, nil
+		}
+		set := sets.NewString()
+		for _, rule := range review.Status.ResourceRules {
+			if !sets.NewString(rule.Verbs...).HasAny("list", "*") {
+				continue
+			}
+			for _, group := range rule.APIGroups {
+				for _, resource := range rule.Resources {
+					set.Insert(group + "/" + resource)
+				}
+			}
+		}
+		m.allowed[scope] = set
+	}
+	return m, nil
 }
 
-// getAPIResources returns all API resources that exists on the cluster.
-func (c *client) getAPIResources(_ context.Context) ([]APIResource, error) {
-	rls, err := c.discoveryClient.ServerPreferredResources()
+// getAPIResources returns all API resources that exists on the cluster,
+// restricted to those matching include (if non-empty) and not matching
+// exclude; both are glob patterns against "group/kind".
+func (c *client) getAPIResources(_ context.Context, include, exclude []string) ([]APIResource, error) {
+	rls, err := c.serverPreferredResources()
 	if err != nil {
 		return nil, err
 	}
 
 	apis := []APIResource{}
+	seen := sets.NewString()
 	for _, rl := range rls {
 		if len(rl.APIResources) == 0 {
 			continue
@@ -277,6 +503,19 @@ func (c *client) getAPIResources(_ context.Context) ([]APIResource, error) {
 				klog.V(4).Infof("Exclude duplicated discovered resource: %s", api)
 				continue
 			}
+			// The aggregated discovery endpoint can return the same kind
+			// served by multiple, otherwise-preferred GroupVersions (e.g.
+			// during a version migration); keep only the first one seen.
+			key := api.Group + "/" + api.Kind
+			if seen.Has(key) {
+				klog.V(4).Infof("Exclude duplicated discovered resource: %s", api)
+				continue
+			}
+			seen.Insert(key)
+			if !matchesAnyAPIResourceGlob(api, include, true) || matchesAnyAPIResourceGlob(api, exclude, false) {
+				klog.V(4).Infof("Exclude resource via --include-resource/--exclude-resource filter: %s", api)
+				continue
+			}
 			apis = append(apis, api)
 		}
 	}
@@ -285,9 +524,127 @@ func (c *client) getAPIResources(_ context.Context) ([]APIResource, error) {
 	return apis, nil
 }
 
-// listByAPI list all objects of the provided API & namespace. If listing the
-// API at the cluster scope, set the namespace argument as an empty string.
-func (c *client) listByAPI(ctx context.Context, api APIResource, ns string) (*unstructuredv1.UnstructuredList, error) {
+// matchesAnyAPIResourceGlob reports whether api's "group/kind" matches any
+// of the given glob patterns. An empty pattern list matches everything when
+// matchEmpty is true (the default-include behavior for IncludeAPIResources)
+// and nothing when matchEmpty is false (the default-exclude behavior for
+// ExcludeAPIResources).
+func matchesAnyAPIResourceGlob(api APIResource, patterns []string, matchEmpty bool) bool {
+	if len(patterns) == 0 {
+		return matchEmpty
+	}
+	key := api.Group + "/" + api.Kind
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, key); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// serverPreferredResources returns the preferred API resources known to the
+// server. It prefers the aggregated discovery endpoint introduced in
+// Kubernetes v1.27, which returns every group, version & resource in a
+// single request instead of the O(groups) round-trips the legacy discovery
+// API requires, and falls back to the legacy, per-group discovery when the
+// server doesn't advertise the aggregated endpoint (HTTP 406, or an older
+// apiserver).
+func (c *client) serverPreferredResources() ([]*metav1.APIResourceList, error) {
+	agg, ok := c.discoveryClient.(discovery.AggregatedDiscoveryInterface)
+	if !ok {
+		return c.discoveryClient.ServerPreferredResources()
+	}
+
+	groups, rls, err := agg.ServerGroupsAndResources()
+	switch {
+	case err == nil:
+		return preferredResourceLists(groups, rls), nil
+	case apierrors.IsNotAcceptable(err):
+		klog.V(4).Infof("Server doesn't advertise aggregated discovery, falling back to legacy discovery: %v", err)
+		return c.discoveryClient.ServerPreferredResources()
+	default:
+		return rls, err
+	}
+}
+
+// preferredResourceLists filters rls down to, for each group, only the
+// APIResourceList for that group's actual preferred version as reported in
+// groups. Unlike ServerPreferredResources, ServerGroupsAndResources returns
+// every served GroupVersion of every group, not just the preferred one, so
+// this is needed to restore the "one version per group" semantics callers
+// of getAPIResources rely on. A group missing from groups (which shouldn't
+// happen, but the API doesn't guarantee it) is passed through unfiltered.
+func preferredResourceLists(groups []*metav1.APIGroup, rls []*metav1.APIResourceList) []*metav1.APIResourceList {
+	preferred := make(map[string]string, len(groups))
+	for _, g := range groups {
+		preferred[g.Name] = g.PreferredVersion.GroupVersion
+	}
+	filtered := make([]*metav1.APIResourceList, 0, len(rls))
+	for _, rl := range rls {
+		gv, err := schema.ParseGroupVersion(rl.GroupVersion)
+		if err != nil {
+			klog.V(4).Infof("Ignoring invalid discovered resource %q: %v", rl.GroupVersion, err)
+			continue
+		}
+		if pref, ok := preferred[gv.Group]; ok && pref != rl.GroupVersion {
+			continue
+		}
+		filtered = append(filtered, rl)
+	}
+	return filtered
+}
+
+// listBackoffStart, listBackoffCap & listBackoffSteps bound the
+// retry-with-backoff applied to an individual List request that's throttled
+// by the API server.
+const (
+	listBackoffStart = 1 * time.Second
+	listBackoffCap   = 30 * time.Second
+	listBackoffSteps = 5
+)
+
+// listWithBackoff performs a single List request, retrying with exponential
+// backoff when the API server responds with HTTP 429 (too many requests).
+// The server's Retry-After is honored when present; otherwise backoff starts
+// at listBackoffStart & doubles up to listBackoffCap. Any other error is
+// returned immediately.
+func (c *client) listWithBackoff(ctx context.Context, ri dynamic.ResourceInterface, opts metav1.ListOptions) (*unstructuredv1.UnstructuredList, error) {
+	backoff := listBackoffStart
+	var lastErr error
+	for attempt := 0; attempt < listBackoffSteps; attempt++ {
+		objectList, err := ri.List(ctx, opts)
+		if err == nil {
+			return objectList, nil
+		}
+		if !apierrors.IsTooManyRequests(err) {
+			return nil, err
+		}
+		lastErr = err
+		if attempt == listBackoffSteps-1 {
+			break
+		}
+
+		delay := backoff
+		if seconds, ok := apierrors.SuggestsClientDelay(err); ok {
+			delay = time.Duration(seconds) * time.Second
+		}
+		klog.V(4).Infof("Throttled listing resource, retrying in %s: %v", delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if backoff < listBackoffCap {
+			backoff *= 2
+		}
+	}
+	return nil, lastErr
+}
+
+// listByAPI list all objects of the provided API & namespace, optionally
+// restricted by labelSelector/fieldSelector. If listing the API at the
+// cluster scope, set the namespace argument as an empty string.
+func (c *client) listByAPI(ctx context.Context, api APIResource, ns string, labelSelector, fieldSelector string) (*unstructuredv1.UnstructuredList, error) {
 	var ri dynamic.ResourceInterface
 	var items []unstructuredv1.Unstructured
 	var next string
@@ -299,9 +656,11 @@ func (c *client) listByAPI(ctx context.Context, api APIResource, ns string) (*un
 		ri = c.dynamicClient.Resource(api.GroupVersionResource()).Namespace(ns)
 	}
 	for {
-		objectList, err := ri.List(ctx, metav1.ListOptions{
-			Limit:    250,
-			Continue: next,
+		objectList, err := c.listWithBackoff(ctx, ri, metav1.ListOptions{
+			Limit:         250,
+			Continue:      next,
+			LabelSelector: labelSelector,
+			FieldSelector: fieldSelector,
 		})
 		if err != nil {
 			switch {
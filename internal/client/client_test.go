@@ -0,0 +1,245 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestAccessMatrixAllows(t *testing.T) {
+	pods := APIResource{Group: "", Name: "pods"}
+	deployments := APIResource{Group: "apps", Name: "deployments"}
+
+	tests := []struct {
+		name  string
+		m     *accessMatrix
+		api   APIResource
+		scope string
+		want  bool
+	}{
+		{
+			name: "nil matrix imposes no restriction",
+			m:    nil,
+			api:  pods,
+			want: true,
+		},
+		{
+			name:  "unknown scope is forbidden",
+			m:     &accessMatrix{allowed: map[string]sets.String{"": sets.NewString("/pods")}},
+			api:   pods,
+			scope: "kube-system",
+			want:  false,
+		},
+		{
+			name: "exact group/resource match",
+			m:    &accessMatrix{allowed: map[string]sets.String{"": sets.NewString("/pods")}},
+			api:  pods,
+			want: true,
+		},
+		{
+			name: "group wildcard match",
+			m:    &accessMatrix{allowed: map[string]sets.String{"": sets.NewString("apps/*")}},
+			api:  deployments,
+			want: true,
+		},
+		{
+			name: "resource wildcard match across groups",
+			m:    &accessMatrix{allowed: map[string]sets.String{"": sets.NewString("*/deployments")}},
+			api:  deployments,
+			want: true,
+		},
+		{
+			name: "full wildcard match",
+			m:    &accessMatrix{allowed: map[string]sets.String{"": sets.NewString("*/*")}},
+			api:  pods,
+			want: true,
+		},
+		{
+			name: "no matching rule is forbidden",
+			m:    &accessMatrix{allowed: map[string]sets.String{"": sets.NewString("apps/deployments")}},
+			api:  pods,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.allows(tt.api, tt.scope); got != tt.want {
+				t.Errorf("allows(%+v, %q) = %v, want %v", tt.api, tt.scope, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesAnyAPIResourceGlob(t *testing.T) {
+	deployment := APIResource{Group: "apps", Kind: "Deployment"}
+
+	tests := []struct {
+		name       string
+		api        APIResource
+		patterns   []string
+		matchEmpty bool
+		want       bool
+	}{
+		{
+			name:       "empty patterns default to matchEmpty=true",
+			api:        deployment,
+			matchEmpty: true,
+			want:       true,
+		},
+		{
+			name:       "empty patterns default to matchEmpty=false",
+			api:        deployment,
+			matchEmpty: false,
+			want:       false,
+		},
+		{
+			name:     "exact group/kind match",
+			api:      deployment,
+			patterns: []string{"apps/Deployment"},
+			want:     true,
+		},
+		{
+			name:     "group glob match",
+			api:      deployment,
+			patterns: []string{"*.k8s.io/*", "apps/*"},
+			want:     true,
+		},
+		{
+			name:     "kind glob match",
+			api:      deployment,
+			patterns: []string{"apps/Depl*"},
+			want:     true,
+		},
+		{
+			name:     "no pattern matches",
+			api:      deployment,
+			patterns: []string{"batch/*", "events"},
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesAnyAPIResourceGlob(tt.api, tt.patterns, tt.matchEmpty); got != tt.want {
+				t.Errorf("matchesAnyAPIResourceGlob(%+v, %v, %v) = %v, want %v", tt.api, tt.patterns, tt.matchEmpty, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestListRetriesPerNamespaceWhenAccessMatrixDeniesClusterScope exercises
+// List's CheckAccess path end-to-end with an access matrix that denies the
+// cluster scope but allows a specific namespace, guarding against the
+// cluster-scope denial being treated as "nothing left to list" instead of
+// falling through to a per-namespace retry.
+func TestListRetriesPerNamespaceWhenAccessMatrixDeniesClusterScope(t *testing.T) {
+	api := APIResource{Group: "example.com", Version: "v1", Name: "widgets", Kind: "Widget", Namespaced: true}
+
+	scheme := runtime.NewScheme()
+	listKinds := map[schema.GroupVersionResource]string{widgetGVR: "WidgetList"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, listKinds, newWidget("ns-a", "foo"))
+
+	authz := fake.NewSimpleClientset()
+	authz.PrependReactor("create", "selfsubjectrulesreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectRulesReview)
+		resp := &authorizationv1.SelfSubjectRulesReview{}
+		// Only the "ns-a" scope is allowed to list widgets; the cluster
+		// scope ("") gets no rules back, i.e. is denied.
+		if review.Spec.Namespace == "ns-a" {
+			resp.Status.ResourceRules = []authorizationv1.ResourceRule{
+				{Verbs: []string{"list"}, APIGroups: []string{"example.com"}, Resources: []string{"widgets"}},
+			}
+		}
+		return true, resp, nil
+	})
+
+	c := &client{
+		configFlags:         &Flags{},
+		dynamicClient:       dyn,
+		authorizationClient: authz.AuthorizationV1(),
+	}
+
+	list, err := c.List(context.Background(), ListOptions{
+		APIResources: []APIResource{api},
+		Namespaces:   []string{"", "ns-a"},
+		CheckAccess:  true,
+	})
+	if err != nil {
+		t.Fatalf("List() returned error: %v", err)
+	}
+	if got, want := len(list.Items), 1; got != want {
+		t.Fatalf("got %d items, want %d; a cluster-scope access-matrix denial must still retry per-namespace instead of dropping the resource", got, want)
+	}
+}
+
+func TestPreferredResourceLists(t *testing.T) {
+	groups := []*metav1.APIGroup{
+		{Name: "apps", PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "apps/v1"}},
+	}
+	rls := []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod"}}},
+		{GroupVersion: "apps/v1beta1", APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment"}}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment"}}},
+	}
+
+	got := preferredResourceLists(groups, rls)
+
+	var gotGVs []string
+	for _, rl := range got {
+		gotGVs = append(gotGVs, rl.GroupVersion)
+	}
+	want := []string{"v1", "apps/v1"}
+	if len(gotGVs) != len(want) {
+		t.Fatalf("got GroupVersions %v, want %v", gotGVs, want)
+	}
+	for i := range want {
+		if gotGVs[i] != want[i] {
+			t.Fatalf("got GroupVersions %v, want %v", gotGVs, want)
+		}
+	}
+}
+
+func TestResolveListOptions(t *testing.T) {
+	c := &client{configFlags: &Flags{
+		LabelSelector:       "app=from-flags",
+		FieldSelector:       "status.phase=Running",
+		IncludeAPIResources: []string{"*.k8s.io/*"},
+		ExcludeAPIResources: []string{"events"},
+	}}
+
+	t.Run("fills unset fields from Flags", func(t *testing.T) {
+		got := c.resolveListOptions(ListOptions{})
+		if got.LabelSelector != "app=from-flags" {
+			t.Errorf("LabelSelector = %q, want the --selector flag's value", got.LabelSelector)
+		}
+		if got.FieldSelector != "status.phase=Running" {
+			t.Errorf("FieldSelector = %q, want the --field-selector flag's value", got.FieldSelector)
+		}
+		if len(got.IncludeAPIResources) != 1 || got.IncludeAPIResources[0] != "*.k8s.io/*" {
+			t.Errorf("IncludeAPIResources = %v, want the --include-resource flag's value", got.IncludeAPIResources)
+		}
+		if len(got.ExcludeAPIResources) != 1 || got.ExcludeAPIResources[0] != "events" {
+			t.Errorf("ExcludeAPIResources = %v, want the --exclude-resource flag's value", got.ExcludeAPIResources)
+		}
+	})
+
+	t.Run("caller-specified fields take precedence", func(t *testing.T) {
+		got := c.resolveListOptions(ListOptions{
+			LabelSelector:       "app=from-caller",
+			IncludeAPIResources: []string{"apps/*"},
+		})
+		if got.LabelSelector != "app=from-caller" {
+			t.Errorf("LabelSelector = %q, want the caller-specified value to win", got.LabelSelector)
+		}
+		if len(got.IncludeAPIResources) != 1 || got.IncludeAPIResources[0] != "apps/*" {
+			t.Errorf("IncludeAPIResources = %v, want the caller-specified value to win", got.IncludeAPIResources)
+		}
+	})
+}